@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+)
+
+// aspectRatioTarget is one entry in the classification table: a label and
+// the width/height ratio it represents.
+type aspectRatioTarget struct {
+	label string
+	ratio float64
+}
+
+// aspectRatioTable is checked in order; the first target within
+// aspectRatioTolerance of the measured ratio wins.
+var aspectRatioTable = []aspectRatioTarget{
+	{label: "16:9", ratio: 16.0 / 9.0},
+	{label: "9:16", ratio: 9.0 / 16.0},
+	{label: "4:3", ratio: 4.0 / 3.0},
+	{label: "3:4", ratio: 3.0 / 4.0},
+	{label: "1:1", ratio: 1.0},
+	{label: "21:9", ratio: 21.0 / 9.0},
+}
+
+// aspectRatioTolerance is how far off a measured ratio can be from a table
+// entry, as a fraction of that entry's ratio, and still match it.
+const aspectRatioTolerance = 0.01
+
+type ffprobeStream struct {
+	CodecType    string `json:"codec_type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	SideDataList []struct {
+		Rotation int `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+type ffprobeOutput struct {
+	Streams []ffprobeStream `json:"streams"`
+}
+
+// getAspectRatio shells out to ffprobe and classifies the file's video
+// stream against aspectRatioTable, returning the matching label (e.g.
+// "16:9") or "other" if nothing matches closely enough.
+func getAspectRatio(filePath string) (string, error) {
+	var stdoutBuffer bytes.Buffer
+
+	cmd := exec.Command("ffprobe", "-v", "error", "-print_format", "json", "-show_streams", filePath)
+	cmd.Stdout = &stdoutBuffer
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	var data ffprobeOutput
+	if err := json.Unmarshal(stdoutBuffer.Bytes(), &data); err != nil {
+		return "", err
+	}
+
+	stream, ok := firstVideoStream(data.Streams)
+	if !ok {
+		return "", fmt.Errorf("no valid video stream in ffprobe output")
+	}
+
+	width, height := displayDimensions(stream)
+	if height == 0 {
+		return "", fmt.Errorf("invalid stream dimensions")
+	}
+
+	return getAspectRatioString(float64(width) / float64(height)), nil
+}
+
+// firstVideoStream returns the first stream ffprobe tagged as video, since
+// -show_streams also reports audio (and sometimes subtitle) streams.
+func firstVideoStream(streams []ffprobeStream) (ffprobeStream, bool) {
+	for _, s := range streams {
+		if s.CodecType == "video" && s.Height > 0 {
+			return s, true
+		}
+	}
+	return ffprobeStream{}, false
+}
+
+// displayDimensions swaps width/height for streams rotated 90 or 270
+// degrees (reported by ffprobe as side_data_list rotation), since the
+// stored dimensions describe the unrotated frame.
+func displayDimensions(s ffprobeStream) (width, height int) {
+	width, height = s.Width, s.Height
+	for _, sd := range s.SideDataList {
+		if abs(sd.Rotation)%180 == 90 {
+			width, height = height, width
+		}
+	}
+	return width, height
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// getAspectRatioString classifies a width/height ratio against
+// aspectRatioTable within aspectRatioTolerance, or returns "other".
+func getAspectRatioString(aspectRatio float64) string {
+	for _, target := range aspectRatioTable {
+		if math.Abs(aspectRatio-target.ratio)/target.ratio <= aspectRatioTolerance {
+			return target.label
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// decodeFfprobeStreams is a small helper so tests can express fixtures as
+// raw ffprobe JSON, the same shape getAspectRatio unmarshals in production.
+func decodeFfprobeStreams(t *testing.T, rawJSON string) []ffprobeStream {
+	t.Helper()
+	var out ffprobeOutput
+	if err := json.Unmarshal([]byte(rawJSON), &out); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	return out.Streams
+}
+
+func TestFirstVideoStream(t *testing.T) {
+	streams := decodeFfprobeStreams(t, `{
+		"streams": [
+			{"codec_type": "audio", "width": 0, "height": 0},
+			{"codec_type": "video", "width": 1920, "height": 1080},
+			{"codec_type": "video", "width": 640, "height": 480}
+		]
+	}`)
+
+	stream, ok := firstVideoStream(streams)
+	if !ok {
+		t.Fatal("expected a video stream, got none")
+	}
+	if stream.Width != 1920 || stream.Height != 1080 {
+		t.Errorf("got %dx%d, want first video stream 1920x1080", stream.Width, stream.Height)
+	}
+}
+
+func TestFirstVideoStreamNoneFound(t *testing.T) {
+	streams := decodeFfprobeStreams(t, `{
+		"streams": [
+			{"codec_type": "audio", "width": 0, "height": 0}
+		]
+	}`)
+
+	if _, ok := firstVideoStream(streams); ok {
+		t.Fatal("expected no video stream, got one")
+	}
+}
+
+func TestDisplayDimensionsSwapsOnRotation(t *testing.T) {
+	streams := decodeFfprobeStreams(t, `{
+		"streams": [
+			{
+				"codec_type": "video",
+				"width": 1920,
+				"height": 1080,
+				"side_data_list": [{"rotation": -90}]
+			}
+		]
+	}`)
+
+	width, height := displayDimensions(streams[0])
+	if width != 1080 || height != 1920 {
+		t.Errorf("displayDimensions = %dx%d, want 1080x1920 (swapped for 90deg rotation)", width, height)
+	}
+}
+
+func TestDisplayDimensionsUnrotated(t *testing.T) {
+	streams := decodeFfprobeStreams(t, `{
+		"streams": [
+			{"codec_type": "video", "width": 1920, "height": 1080}
+		]
+	}`)
+
+	width, height := displayDimensions(streams[0])
+	if width != 1920 || height != 1080 {
+		t.Errorf("displayDimensions = %dx%d, want unchanged 1920x1080", width, height)
+	}
+}
+
+func TestDisplayDimensions180DegreesNotSwapped(t *testing.T) {
+	streams := decodeFfprobeStreams(t, `{
+		"streams": [
+			{
+				"codec_type": "video",
+				"width": 1920,
+				"height": 1080,
+				"side_data_list": [{"rotation": 180}]
+			}
+		]
+	}`)
+
+	width, height := displayDimensions(streams[0])
+	if width != 1920 || height != 1080 {
+		t.Errorf("displayDimensions = %dx%d, want unchanged 1920x1080 for a 180deg rotation", width, height)
+	}
+}
+
+func TestGetAspectRatioString(t *testing.T) {
+	tests := []struct {
+		name  string
+		ratio float64
+		want  string
+	}{
+		{"landscape 16:9", 1920.0 / 1080.0, "16:9"},
+		{"portrait 9:16", 1080.0 / 1920.0, "9:16"},
+		{"4:3", 4.0 / 3.0, "4:3"},
+		{"1:1 square", 1.0, "1:1"},
+		{"21:9 ultrawide", 2100.0 / 900.0, "21:9"},
+		{"within tolerance of 16:9", 16.05 / 9.0, "16:9"},
+		{"outside tolerance of anything", 1.5, "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getAspectRatioString(tt.ratio); got != tt.want {
+				t.Errorf("getAspectRatioString(%v) = %q, want %q", tt.ratio, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,12 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/filestore"
 	"github.com/google/uuid"
 )
 
@@ -62,19 +60,13 @@ func (cfg *apiConfig) handlerUploadThumbnail(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	savePath := filepath.Join(cfg.assetsRoot, videoIDString+"."+ext[1])
-	newFile, err := os.Create(savePath)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Error creating the file on the server", err)
-		return
-	}
-	_, err = io.Copy(newFile, file)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Error copying file contents to server", err)
+	key := videoIDString + "." + ext[1]
+	if err := cfg.fileStore.Put(r.Context(), key, file, mediaType); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error storing thumbnail", err)
 		return
 	}
 
-	url := fmt.Sprintf("http://localhost:%s/assets/%s.%s", cfg.port, videoIDString, ext[1])
+	url := filestore.ObjectLocation{Bucket: cfg.s3Bucket, Key: key}.String()
 	video.ThumbnailURL = &url
 
 	err = cfg.db.UpdateVideo(video)
@@ -83,5 +75,11 @@ func (cfg *apiConfig) handlerUploadThumbnail(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	video, err = cfg.dbVideoToSignedVideo(video)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error generating thumbnail URL", err)
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, video)
 }
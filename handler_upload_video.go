@@ -5,23 +5,30 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io"
-	"math"
+	"io/fs"
 	"mime"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
 	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/filestore"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/jobs"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/media"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/mpd"
 	"github.com/google/uuid"
 )
 
+// handlerUploadVideo only has to get the raw upload safely onto disk; the
+// rest of the pipeline (fast-start, aspect detection, transcoding, S3
+// upload) runs on cfg.jobPool, and progress for it is available from
+// handlerVideoProgress.
 func (cfg *apiConfig) handlerUploadVideo(w http.ResponseWriter, r *http.Request) {
 	const maxMemory = 1 << 30
 
@@ -82,149 +89,303 @@ func (cfg *apiConfig) handlerUploadVideo(w http.ResponseWriter, r *http.Request)
 	}
 	filename := base64.RawURLEncoding.EncodeToString(randomFileName) + "." + ext[1]
 
-	tempFile, err := os.CreateTemp("", filename)
+	rawPath := filepath.Join(cfg.assetsRoot, "raw-uploads", filename)
+	if err := os.MkdirAll(filepath.Dir(rawPath), 0o755); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error creating upload directory", err)
+		return
+	}
+
+	rawFile, err := os.Create(rawPath)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Issue creating temporary file", err)
+		respondWithError(w, http.StatusInternalServerError, "Issue creating upload file", err)
 		return
 	}
-	defer os.Remove(tempFile.Name())
-	defer tempFile.Close()
+	defer rawFile.Close()
 
-	if _, err = io.Copy(tempFile, file); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Issue copying to temporary file", err)
+	if _, err = io.Copy(rawFile, file); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Issue copying to upload file", err)
 		return
 	}
 
-	tempFileProcessed, err := processVideoForFastStart(tempFile.Name())
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Issue processing file for fast start", err)
+	job := jobs.Job{
+		ID:         uuid.NewString(),
+		VideoID:    videoID,
+		UserID:     userID,
+		SourcePath: rawPath,
+		Status:     jobs.StatusPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := cfg.jobStore.Create(job); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Issue creating processing job", err)
 		return
 	}
-	processedFilenameSlice := strings.Split(tempFileProcessed, "/")
-	processedFilename := processedFilenameSlice[(len(processedFilenameSlice) - 1)]
+	cfg.jobPool.Enqueue(job.ID)
 
-	processedFile, err := os.Open(tempFileProcessed)
+	respondWithJSON(w, http.StatusAccepted, struct {
+		JobID string `json:"jobId"`
+	}{JobID: job.ID})
+}
+
+// processUploadedVideo is the jobs.Processor run by cfg.jobPool for every
+// enqueued upload: fast-start, aspect detection, peaks extraction, HLS
+// transcoding, DASH packaging, then the fast-start MP4 upload, with report
+// called as each stage starts and finishes.
+func (cfg *apiConfig) processUploadedVideo(ctx context.Context, job *jobs.Job, report func(jobs.Stage, int)) error {
+	defer os.Remove(job.SourcePath)
+
+	report(jobs.StageFastStart, 0)
+	processedPath, err := processVideoForFastStart(job.SourcePath)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Could not find processed file", err)
-		return
+		return fmt.Errorf("fast start: %w", err)
+	}
+	defer os.Remove(processedPath)
+	report(jobs.StageFastStart, 100)
+
+	report(jobs.StageAspect, 0)
+	aspectRatio, err := getAspectRatio(job.SourcePath)
+	if err != nil {
+		return fmt.Errorf("aspect ratio: %w", err)
+	}
+	job.AspectRatio = aspectRatio
+	report(jobs.StageAspect, 100)
+
+	report(jobs.StagePeaks, 0)
+	if err := cfg.extractAndUploadPeaks(ctx, job.SourcePath, aspectRatio, job.VideoID.String()); err != nil {
+		return fmt.Errorf("peaks: %w", err)
+	}
+	report(jobs.StagePeaks, 100)
+
+	report(jobs.StageTranscoding, 0)
+	if _, err := cfg.transcodeAndUploadHLS(ctx, processedPath, aspectRatio, job.VideoID.String()); err != nil {
+		return fmt.Errorf("transcode: %w", err)
+	}
+	report(jobs.StageTranscoding, 100)
+
+	report(jobs.StageDASH, 0)
+	if _, err := cfg.transcodeAndUploadDASH(ctx, processedPath, aspectRatio, job.VideoID.String()); err != nil {
+		return fmt.Errorf("dash packaging: %w", err)
+	}
+	report(jobs.StageDASH, 100)
+
+	report(jobs.StageUploading, 0)
+	processedFile, err := os.Open(processedPath)
+	if err != nil {
+		return fmt.Errorf("open processed file: %w", err)
 	}
 	defer processedFile.Close()
 
-	aspectRatio, err := getAspectRatio(tempFile.Name())
+	info, err := processedFile.Stat()
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Issue finding aspect ratio", err)
-		return
+		return fmt.Errorf("stat processed file: %w", err)
 	}
+
+	processedFilenameSlice := strings.Split(processedPath, "/")
+	processedFilename := processedFilenameSlice[len(processedFilenameSlice)-1]
 	fileKey := aspectRatio + "/" + processedFilename
 
-	tempFile.Seek(0, io.SeekStart)
-	cfg.s3Client.PutObject(r.Context(), &s3.PutObjectInput{
-		Bucket:      &cfg.s3Bucket,
-		Key:         &fileKey,
-		Body:        processedFile,
-		ContentType: &mediaType,
+	progressBody := jobs.NewProgressReader(processedFile, info.Size(), func(pct int) {
+		report(jobs.StageUploading, pct)
 	})
-	url := fmt.Sprintf("%s,%s", cfg.s3Bucket, fileKey)
 
-	video.VideoURL = &url
-	video, err = cfg.dbVideoToSignedVideo(video)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Unable to create signed URL", err)
-		return
+	if err := cfg.fileStore.Put(ctx, fileKey, progressBody, "video/mp4"); err != nil {
+		return fmt.Errorf("upload to file store: %w", err)
 	}
+	report(jobs.StageUploading, 100)
 
-	err = cfg.db.UpdateVideo(video)
+	video, err := cfg.db.GetVideo(job.VideoID)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Video unable to be updated", err)
-		return
+		return fmt.Errorf("load video: %w", err)
 	}
 
-	respondWithJSON(w, http.StatusOK, video)
-}
+	url := filestore.ObjectLocation{Bucket: cfg.s3Bucket, Key: fileKey}.String()
+	video.VideoURL = &url
 
-func getAspectRatio(filePath string) (string, error) {
-	type respStruct struct {
-		Streams []struct {
-			Width  int `json:"width"`
-			Height int `json:"height"`
-		} `json:"streams"`
+	// The master playlist isn't a single object we can hand out a presigned
+	// link to: its renditions and segments are separate private objects the
+	// player needs signed one at a time as it fetches them. So StreamingURL
+	// points at our own handlerVideoHLSAsset route instead of an
+	// ObjectLocation — signStoredURL leaves it untouched since it doesn't
+	// parse as one.
+	streamingURL := "/api/videos/" + job.VideoID.String() + "/hls/master.m3u8"
+	video.StreamingURL = &streamingURL
+
+	// Persist the unsigned ObjectLocation string for VideoURL, not a signed
+	// copy: signed URLs expire in 10 minutes and can't be parsed back by
+	// signStoredURL, so storing them would make the video unplayable
+	// shortly after processing with no way to re-sign it. Callers sign a
+	// copy on read via dbVideoToSignedVideo, the same way
+	// handlerUploadThumbnail does.
+	if err := cfg.db.UpdateVideo(video); err != nil {
+		return fmt.Errorf("update video: %w", err)
 	}
-	var stdoutBuffer bytes.Buffer
 
-	cmd := exec.Command("ffprobe", "-v", "error", "-print_format", "json", "-show_streams", filePath)
-	cmd.Stdout = &stdoutBuffer
+	return nil
+}
+
+func processVideoForFastStart(filepath string) (string, error) {
+	outputFilepath := filepath + ".processing"
 
+	cmd := exec.Command("ffmpeg", "-i", filepath, "-c", "copy", "-movflags", "faststart", "-f", "mp4", outputFilepath)
 	err := cmd.Run()
 	if err != nil {
 		return "", err
 	}
+	return outputFilepath, nil
+}
 
-	data := &respStruct{}
-
-	err = json.Unmarshal(stdoutBuffer.Bytes(), data)
+func (cfg *apiConfig) dbVideoToSignedVideo(video database.Video) (database.Video, error) {
+	signedVideoURL, err := cfg.signStoredURL(video.VideoURL)
 	if err != nil {
-		return "", err
+		return video, err
 	}
-	if len(data.Streams) == 0 || data.Streams[0].Height == 0 {
-		return "", fmt.Errorf("No valid streams in ffprobe output")
+	video.VideoURL = signedVideoURL
+
+	signedStreamingURL, err := cfg.signStoredURL(video.StreamingURL)
+	if err != nil {
+		return video, err
 	}
-	aspRatio := float64(data.Streams[0].Width) / float64(data.Streams[0].Height)
-	aspRatioStr := getAspectRatioString(aspRatio)
-	return aspRatioStr, nil
-}
+	video.StreamingURL = signedStreamingURL
 
-func getAspectRatioString(aspectRatio float64) string {
-	if math.Round(aspectRatio*100)/100 == math.Round(1600.0/9.0)/100.0 {
-		return "landscape"
-	} else if math.Round(aspectRatio*100)/100 == math.Round(900.0/16.0)/100.0 {
-		return "portrait"
+	signedThumbnailURL, err := cfg.signStoredURL(video.ThumbnailURL)
+	if err != nil {
+		return video, err
 	}
+	video.ThumbnailURL = signedThumbnailURL
 
-	return "other"
+	return video, nil
 }
 
-func processVideoForFastStart(filepath string) (string, error) {
-	outputFilepath := filepath + ".processing"
+// signStoredURL turns a stored filestore.ObjectLocation into a presigned
+// URL. It returns the input unchanged if it's nil or not in that form
+// (e.g. it's already a public URL).
+func (cfg *apiConfig) signStoredURL(url *string) (*string, error) {
+	if url == nil {
+		return nil, nil
+	}
 
-	cmd := exec.Command("ffmpeg", "-i", filepath, "-c", "copy", "-movflags", "faststart", "-f", "mp4", outputFilepath)
-	err := cmd.Run()
+	loc, ok := filestore.ParseObjectLocation(*url)
+	if !ok {
+		return url, nil
+	}
+
+	signedURL, err := cfg.fileStore.PresignGet(context.Background(), loc.Key, 10*time.Minute)
 	if err != nil {
-		return "", err
+		return url, err
 	}
-	return outputFilepath, nil
+
+	return &signedURL, nil
 }
 
-func generatePresignedURL(s3Client *s3.Client, bucket, key string, expireTime time.Duration) (string, error) {
-	params := s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &key,
+// transcodeAndUploadHLS builds an adaptive HLS ladder for the processed
+// video and uploads it to S3 under <aspect>/<videoID>/, returning the key of
+// the master playlist. It never produces renditions larger than the source.
+func (cfg *apiConfig) transcodeAndUploadHLS(ctx context.Context, processedFilePath, aspectRatio, videoID string) (string, error) {
+	sourceWidth, sourceHeight, err := media.ProbeDimensions(processedFilePath)
+	if err != nil {
+		return "", err
 	}
-	presignedClient := s3.NewPresignClient(s3Client)
-	req, err := presignedClient.PresignGetObject(context.TODO(), &params, s3.WithPresignExpires(expireTime))
+	ladder := media.LadderFor(sourceWidth, sourceHeight)
+
+	out, err := media.TranscodeHLS(processedFilePath, ladder)
 	if err != nil {
 		return "", err
 	}
+	defer os.RemoveAll(out.Dir)
 
-	return req.URL, nil
+	prefix := aspectRatio + "/" + videoID
+	if err := cfg.uploadMediaTree(ctx, out.Dir, prefix); err != nil {
+		return "", err
+	}
+
+	return prefix + "/master.m3u8", nil
 }
 
-func (cfg *apiConfig) dbVideoToSignedVideo(video database.Video) (database.Video, error) {
-	if video.VideoURL == nil {
-		return video, nil
+// transcodeAndUploadDASH packages the processed video as a DASH manifest
+// plus init/media segments, validates the manifest, and uploads the whole
+// tree under <aspect>/<videoID>/dash/, returning the manifest's key.
+func (cfg *apiConfig) transcodeAndUploadDASH(ctx context.Context, processedFilePath, aspectRatio, videoID string) (string, error) {
+	dir, manifestPath, err := mpd.GenerateDASH(processedFilePath)
+	if err != nil {
+		return "", err
 	}
+	defer os.RemoveAll(dir)
 
-	videoURLSlice := strings.Split(*video.VideoURL, ",")
-	if len(videoURLSlice) != 2 {
-		return video, nil
+	manifestBytes, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", err
 	}
-	bucket := videoURLSlice[0]
-	key := videoURLSlice[1]
-	signedVideoURL, err := generatePresignedURL(cfg.s3Client, bucket, key, 10*time.Minute)
+	doc, err := mpd.Parse(manifestBytes)
 	if err != nil {
-		return video, err
+		return "", err
+	}
+	if err := doc.Validate(); err != nil {
+		return "", fmt.Errorf("generated manifest failed validation: %w", err)
 	}
 
-	video.VideoURL = &signedVideoURL
+	prefix := aspectRatio + "/" + videoID + "/dash"
+	if err := cfg.uploadMediaTree(ctx, dir, prefix); err != nil {
+		return "", err
+	}
 
-	return video, nil
+	return prefix + "/manifest.mpd", nil
+}
+
+// extractAndUploadPeaks generates waveform peaks for sourcePath's audio
+// track and uploads the encoded blob at <aspect>/<videoID>.peaks.
+func (cfg *apiConfig) extractAndUploadPeaks(ctx context.Context, sourcePath, aspectRatio, videoID string) error {
+	peaks, err := media.ExtractPeaks(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	key := aspectRatio + "/" + videoID + ".peaks"
+	body := bytes.NewReader(media.EncodePeaks(peaks))
+	return cfg.fileStore.Put(ctx, key, body, "application/octet-stream")
+}
+
+// uploadMediaTree walks a transcoder's output directory (an HLS ladder's
+// master.m3u8 plus stream_<rendition>/ subdirectories, or a DASH manifest
+// plus its init/media segments) and uploads every file under keyPrefix,
+// preserving the relative layout.
+func (cfg *apiConfig) uploadMediaTree(ctx context.Context, dir, keyPrefix string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		key := keyPrefix + "/" + filepath.ToSlash(rel)
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return cfg.fileStore.Put(ctx, key, f, mediaContentType(path))
+	})
+}
+
+// mediaContentType returns the MIME type for a file produced by the HLS or
+// DASH packagers, based on its extension.
+func mediaContentType(path string) string {
+	switch {
+	case strings.HasSuffix(path, ".m3u8"):
+		return "application/vnd.apple.mpegurl"
+	case strings.HasSuffix(path, ".ts"):
+		return "video/mp2t"
+	case strings.HasSuffix(path, ".mpd"):
+		return "application/dash+xml"
+	case strings.HasSuffix(path, ".m4s"), strings.HasSuffix(path, ".m4v"), strings.HasSuffix(path, ".m4a"):
+		return "video/iso.segment"
+	default:
+		return "application/octet-stream"
+	}
 }
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/filestore"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/jobs"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/uploads"
+	"github.com/google/uuid"
+)
+
+// handlerCreateUploadSession starts a resumable, tus-style upload: it opens
+// a multipart upload on the FileStore and hands back a session ID the
+// client will use for every subsequent part.
+func (cfg *apiConfig) handlerCreateUploadSession(w http.ResponseWriter, r *http.Request) {
+	videoID, userID, ok := cfg.authorizeVideoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	key := fmt.Sprintf("uploads/%s/%s.mp4", videoID, uuid.NewString())
+	mpUpload, err := cfg.fileStore.InitMultipart(r.Context(), key, "video/mp4")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start upload session", err)
+		return
+	}
+
+	now := time.Now()
+	session := uploads.Session{
+		ID:        uuid.NewString(),
+		VideoID:   videoID,
+		UserID:    userID,
+		Key:       key,
+		UploadID:  mpUpload.UploadID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := cfg.uploadStore.Create(session); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't persist upload session", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, struct {
+		UploadID string `json:"uploadId"`
+	}{UploadID: session.ID})
+}
+
+// handlerGetUploadSession reports which parts have already landed, so a
+// client resuming after a network drop knows what it still needs to send.
+func (cfg *apiConfig) handlerGetUploadSession(w http.ResponseWriter, r *http.Request) {
+	videoID, userID, ok := cfg.authorizeVideoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := cfg.uploadStore.Get(r.PathValue("uploadID"))
+	if err != nil || session.VideoID != videoID || session.UserID != userID {
+		respondWithError(w, http.StatusNotFound, "Upload session not found", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct {
+		Completed        bool    `json:"completed"`
+		ReceivedPartNums []int32 `json:"receivedPartNumbers"`
+	}{Completed: session.Completed, ReceivedPartNums: session.ReceivedPartNumbers()})
+}
+
+// maxPartSize caps a single resumable upload part. It's well above the 5MB
+// S3 requires for all but the last part, and well below the 5GB S3 allows,
+// so a client streaming one outsized PATCH can't exhaust disk via the
+// temp file handlerUploadPart buffers each part into.
+const maxPartSize = 100 << 20
+
+// handlerUploadPart buffers one part of a resumable upload into a temp file,
+// hashing it on the way, then uploads it as a part of the FileStore's
+// underlying multipart upload.
+func (cfg *apiConfig) handlerUploadPart(w http.ResponseWriter, r *http.Request) {
+	videoID, userID, ok := cfg.authorizeVideoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := cfg.uploadStore.Get(r.PathValue("uploadID"))
+	if err != nil || session.VideoID != videoID || session.UserID != userID {
+		respondWithError(w, http.StatusNotFound, "Upload session not found", err)
+		return
+	}
+	if session.Completed {
+		respondWithError(w, http.StatusConflict, "Upload session already completed", nil)
+		return
+	}
+
+	partNumber, err := strconv.ParseInt(r.URL.Query().Get("partNumber"), 10, 32)
+	if err != nil || partNumber < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid partNumber", err)
+		return
+	}
+
+	partFile, err := os.CreateTemp("", "upload-part-*")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't buffer part", err)
+		return
+	}
+	defer os.Remove(partFile.Name())
+	defer partFile.Close()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPartSize)
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(partFile, hasher), r.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read part body", err)
+		return
+	}
+	if _, err := partFile.Seek(0, io.SeekStart); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rewind part", err)
+		return
+	}
+
+	mpUpload := filestore.MultipartUpload{Key: session.Key, UploadID: session.UploadID}
+	completed, err := cfg.fileStore.UploadPart(r.Context(), mpUpload, int32(partNumber), partFile)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't upload part", err)
+		return
+	}
+
+	part := uploads.PartRecord{
+		PartNumber: completed.PartNumber,
+		ETag:       completed.ETag,
+		Size:       size,
+		Checksum:   hex.EncodeToString(hasher.Sum(nil)),
+	}
+	if err := cfg.uploadStore.AddPart(session.ID, part); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record part", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, part)
+}
+
+// handlerCompleteUpload finalizes a resumable upload: it stitches the
+// FileStore's parts into a single object, then hands that object to the
+// same background job pipeline a regular upload uses.
+func (cfg *apiConfig) handlerCompleteUpload(w http.ResponseWriter, r *http.Request) {
+	videoID, userID, ok := cfg.authorizeVideoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := cfg.uploadStore.Get(r.PathValue("uploadID"))
+	if err != nil || session.VideoID != videoID || session.UserID != userID {
+		respondWithError(w, http.StatusNotFound, "Upload session not found", err)
+		return
+	}
+	if len(session.Parts) == 0 {
+		respondWithError(w, http.StatusBadRequest, "No parts received for this upload", nil)
+		return
+	}
+
+	sortedParts := append([]uploads.PartRecord(nil), session.Parts...)
+	sort.Slice(sortedParts, func(i, j int) bool { return sortedParts[i].PartNumber < sortedParts[j].PartNumber })
+
+	completedParts := make([]filestore.CompletedPart, len(sortedParts))
+	for i, p := range sortedParts {
+		completedParts[i] = filestore.CompletedPart{PartNumber: p.PartNumber, ETag: p.ETag}
+	}
+
+	mpUpload := filestore.MultipartUpload{Key: session.Key, UploadID: session.UploadID}
+	if err := cfg.fileStore.CompleteMultipart(r.Context(), mpUpload, completedParts); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't complete upload", err)
+		return
+	}
+	if err := cfg.uploadStore.Complete(session.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark upload complete", err)
+		return
+	}
+
+	rawPath, err := cfg.copyAssembledUploadToDisk(r.Context(), session)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't stage assembled upload", err)
+		return
+	}
+
+	job := jobs.Job{
+		ID:         uuid.NewString(),
+		VideoID:    videoID,
+		UserID:     userID,
+		SourcePath: rawPath,
+		Status:     jobs.StatusPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := cfg.jobStore.Create(job); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Issue creating processing job", err)
+		return
+	}
+	cfg.jobPool.Enqueue(job.ID)
+
+	respondWithJSON(w, http.StatusAccepted, struct {
+		JobID string `json:"jobId"`
+	}{JobID: job.ID})
+}
+
+// copyAssembledUploadToDisk pulls the now-complete object back out of the
+// FileStore so the ffmpeg-based job pipeline, which only knows how to read
+// local files, can process it exactly like a direct upload would.
+func (cfg *apiConfig) copyAssembledUploadToDisk(ctx context.Context, session uploads.Session) (string, error) {
+	rawPath := filepath.Join(cfg.assetsRoot, "raw-uploads", uuid.NewString()+".mp4")
+	if err := os.MkdirAll(filepath.Dir(rawPath), 0o755); err != nil {
+		return "", err
+	}
+
+	src, err := cfg.fileStore.Get(ctx, session.Key)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(rawPath)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(rawPath)
+		return "", err
+	}
+	return rawPath, nil
+}
+
+// authorizeVideoUpload runs the auth + ownership check shared by every
+// resumable-upload endpoint.
+func (cfg *apiConfig) authorizeVideoUpload(w http.ResponseWriter, r *http.Request) (videoID, userID uuid.UUID, ok bool) {
+	videoID, err := uuid.Parse(r.PathValue("videoID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ID", err)
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	userID, err = auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	video, err := cfg.db.GetVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Video Not Found", err)
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized user", nil)
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	return videoID, userID, true
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/media"
+	"github.com/google/uuid"
+)
+
+// handlerVideoHLSAsset serves an HLS playlist for a video — either the top
+// master.m3u8 or a rendition's stream_<name>/stream.m3u8 — signing every
+// segment it references on the way out. Nested rendition playlists are
+// left as relative links, since they resolve straight back to this same
+// route; only the leaf .ts segments, which would otherwise point unsigned
+// at the private bucket, get presigned. This is the HLS equivalent of
+// handlerVideoManifest's RewriteBaseURLs for DASH.
+func (cfg *apiConfig) handlerVideoHLSAsset(w http.ResponseWriter, r *http.Request) {
+	videoIDString := r.PathValue("videoID")
+	videoID, err := uuid.Parse(videoIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	video, err := cfg.db.GetVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Video Not Found", err)
+		return
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized user", nil)
+		return
+	}
+
+	job, err := cfg.jobStore.LatestForVideo(videoID)
+	if err != nil || job.AspectRatio == "" {
+		respondWithError(w, http.StatusNotFound, "No stream available for video", err)
+		return
+	}
+
+	assetPath := r.PathValue("assetPath")
+	if !strings.HasSuffix(assetPath, ".m3u8") {
+		respondWithError(w, http.StatusBadRequest, "Not a playlist", nil)
+		return
+	}
+
+	prefix := job.AspectRatio + "/" + videoID.String()
+	obj, err := cfg.fileStore.Get(r.Context(), prefix+"/"+assetPath)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't fetch playlist", err)
+		return
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't read playlist", err)
+		return
+	}
+
+	// Segment lines in a rendition playlist are relative to that
+	// playlist's own directory, not the video's top-level prefix.
+	segmentDir := path.Dir(assetPath)
+	rewritten, err := media.RewritePlaylist(data, func(relPath string) (string, error) {
+		if strings.HasSuffix(relPath, ".m3u8") {
+			return relPath, nil
+		}
+		segmentKey := prefix + "/" + relPath
+		if segmentDir != "." {
+			segmentKey = prefix + "/" + segmentDir + "/" + relPath
+		}
+		return cfg.fileStore.PresignGet(r.Context(), segmentKey, 10*time.Minute)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't sign playlist segments", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Write(rewritten)
+}
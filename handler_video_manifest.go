@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/mpd"
+	"github.com/google/uuid"
+)
+
+// handlerVideoManifest serves the DASH manifest for a video, rewriting every
+// representation's BaseURL to a freshly-signed URL on every request — those
+// expire faster than we'd want to cache the manifest for, so they can't be
+// baked in at transcode time.
+func (cfg *apiConfig) handlerVideoManifest(w http.ResponseWriter, r *http.Request) {
+	videoIDString := r.PathValue("videoID")
+	videoID, err := uuid.Parse(videoIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	video, err := cfg.db.GetVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Video Not Found", err)
+		return
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized user", nil)
+		return
+	}
+
+	job, err := cfg.jobStore.LatestForVideo(videoID)
+	if err != nil || job.AspectRatio == "" {
+		respondWithError(w, http.StatusNotFound, "No manifest available for video", err)
+		return
+	}
+
+	prefix := job.AspectRatio + "/" + videoID.String() + "/dash"
+	obj, err := cfg.fileStore.Get(r.Context(), prefix+"/manifest.mpd")
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't fetch manifest", err)
+		return
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't read manifest", err)
+		return
+	}
+
+	doc, err := mpd.Parse(data)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't parse manifest", err)
+		return
+	}
+
+	err = doc.RewriteBaseURLs(func(relPath string) (string, error) {
+		return cfg.fileStore.PresignGet(r.Context(), prefix+"/"+relPath, 10*time.Minute)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't sign manifest segments", err)
+		return
+	}
+
+	signed, err := doc.Marshal()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't serialize manifest", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/dash+xml")
+	w.Write(signed)
+}
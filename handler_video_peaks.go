@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/media"
+	"github.com/google/uuid"
+)
+
+// handlerVideoPeaks serves the waveform peaks generated for a video's audio
+// track. It returns the raw binary blob unless the caller asks for JSON via
+// ?format=json, in which case the peaks are decoded into a plain array for
+// the frontend to draw a waveform from.
+func (cfg *apiConfig) handlerVideoPeaks(w http.ResponseWriter, r *http.Request) {
+	videoIDString := r.PathValue("videoID")
+	videoID, err := uuid.Parse(videoIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	video, err := cfg.db.GetVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Video Not Found", err)
+		return
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized user", nil)
+		return
+	}
+
+	job, err := cfg.jobStore.LatestForVideo(videoID)
+	if err != nil || job.AspectRatio == "" {
+		respondWithError(w, http.StatusNotFound, "No peaks available for video", err)
+		return
+	}
+
+	key := job.AspectRatio + "/" + videoID.String() + ".peaks"
+	obj, err := cfg.fileStore.Get(r.Context(), key)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't fetch peaks", err)
+		return
+	}
+	defer obj.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(obj); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't read peaks", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "json" {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(buf.Bytes())
+		return
+	}
+
+	peaks, err := media.DecodePeaks(buf.Bytes())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode peaks", err)
+		return
+	}
+
+	type peakJSON struct {
+		Min int16 `json:"min"`
+		Max int16 `json:"max"`
+	}
+	out := make([]peakJSON, len(peaks))
+	for i, p := range peaks {
+		out[i] = peakJSON{Min: p.Min, Max: p.Max}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		fmt.Println("error encoding peaks response:", err)
+	}
+}
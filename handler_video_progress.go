@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/jobs"
+	"github.com/google/uuid"
+)
+
+// handlerVideoProgress streams the state of the most recent upload-processing
+// job for a video as Server-Sent Events: one immediate snapshot from the
+// job store, then one event per stage/percent update until the job reaches
+// a terminal status.
+func (cfg *apiConfig) handlerVideoProgress(w http.ResponseWriter, r *http.Request) {
+	videoIDString := r.PathValue("videoID")
+	videoID, err := uuid.Parse(videoIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	video, err := cfg.db.GetVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Video Not Found", err)
+		return
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized user", nil)
+		return
+	}
+
+	job, err := cfg.jobStore.LatestForVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "No processing job found for video", err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "Streaming not supported", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent := func(evt jobs.Event) bool {
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	// Subscribe before re-reading the job, not after: Pool always writes a
+	// terminal status to the store before it Publishes the matching event,
+	// so once we're subscribed, a job that finishes concurrently either
+	// shows up here as an already-terminal row or arrives as an event on
+	// the channel below — never both dropped. Subscribing after the read
+	// (the previous order) left a window where a job finishing between the
+	// read and the Subscribe call published to no one, and the stream
+	// would then wait forever for an event that already happened.
+	events, cancel := cfg.jobHub.Subscribe(job.ID)
+	defer cancel()
+
+	// Headers are already committed, so fall back to the pre-subscribe
+	// snapshot rather than erroring out if the re-read fails.
+	if fresh, err := cfg.jobStore.Get(job.ID); err == nil {
+		job = fresh
+	}
+
+	current := jobs.Event{Status: job.Status, Stage: job.Stage, Percent: job.Percent, Error: job.Error}
+	if !writeEvent(current) {
+		return
+	}
+	if job.Status == jobs.StatusDone || job.Status == jobs.StatusFailed {
+		return
+	}
+
+	// The Hub skips events for a subscriber whose buffer is full, so also
+	// poll the store: a missed terminal event still ends the stream.
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fresh, err := cfg.jobStore.Get(job.ID)
+			if err != nil || (fresh.Status != jobs.StatusDone && fresh.Status != jobs.StatusFailed) {
+				continue
+			}
+			writeEvent(jobs.Event{Status: fresh.Status, Stage: fresh.Stage, Percent: fresh.Percent, Error: fresh.Error})
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			if !writeEvent(evt) {
+				return
+			}
+			if evt.Status == jobs.StatusDone || evt.Status == jobs.StatusFailed {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package filestore abstracts where uploaded bytes live so handlers don't
+// talk to *s3.Client directly. S3FileStore backs production; LocalFileStore
+// writes under assetsRoot and serves through the existing /assets handler,
+// so the app runs without AWS credentials for dev and tests.
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// MultipartUpload identifies an in-progress multipart upload.
+type MultipartUpload struct {
+	Key      string
+	UploadID string
+}
+
+// CompletedPart is one finished part of a multipart upload, returned by
+// UploadPart and fed back into CompleteMultipart once every part has
+// landed.
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// FileStore is everything the handlers need from an object store.
+type FileStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+	InitMultipart(ctx context.Context, key, contentType string) (MultipartUpload, error)
+	UploadPart(ctx context.Context, upload MultipartUpload, partNumber int32, body io.ReadSeeker) (CompletedPart, error)
+	CompleteMultipart(ctx context.Context, upload MultipartUpload, parts []CompletedPart) error
+	Delete(ctx context.Context, key string) error
+}
+
+// ObjectLocation identifies an object within a FileStore. It replaces the
+// old "bucket,key" comma-encoded string stored on Video/Thumbnail URLs.
+type ObjectLocation struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+// String serializes the location so it can be stored in a single DB column.
+func (l ObjectLocation) String() string {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// ParseObjectLocation parses the output of ObjectLocation.String. ok is
+// false if s isn't a serialized ObjectLocation, so callers can fall back to
+// treating it as an already-public URL.
+func ParseObjectLocation(s string) (loc ObjectLocation, ok bool) {
+	if err := json.Unmarshal([]byte(s), &loc); err != nil {
+		return ObjectLocation{}, false
+	}
+	return loc, true
+}
@@ -0,0 +1,117 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocalFileStore writes objects under root and serves them back out from
+// baseURL (the existing /assets handler), so the app runs without AWS
+// credentials for dev and tests.
+type LocalFileStore struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalFileStore returns a FileStore rooted at root, whose objects are
+// reachable at baseURL+"/"+key (e.g. "http://localhost:8091/assets").
+func NewLocalFileStore(root, baseURL string) *LocalFileStore {
+	return &LocalFileStore{root: root, baseURL: baseURL}
+}
+
+func (f *LocalFileStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	path := filepath.Join(f.root, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, body)
+	return err
+}
+
+func (f *LocalFileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(f.root, key))
+}
+
+// PresignGet ignores expires: local assets are served straight off disk with
+// no expiry, so the "presigned" URL is just the permanent asset URL.
+func (f *LocalFileStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return f.baseURL + "/" + key, nil
+}
+
+// InitMultipart has no server-side concept to reserve; parts are buffered
+// under a staging directory keyed by a random upload ID until
+// CompleteMultipart stitches them together. The ID is random rather than
+// derived from key so that concurrent or retried sessions for the same
+// video get independent staging directories, matching S3FileStore.
+func (f *LocalFileStore) InitMultipart(ctx context.Context, key, contentType string) (MultipartUpload, error) {
+	uploadID := uuid.NewString()
+	if err := os.MkdirAll(f.partsDir(uploadID), 0o755); err != nil {
+		return MultipartUpload{}, err
+	}
+	return MultipartUpload{Key: key, UploadID: uploadID}, nil
+}
+
+func (f *LocalFileStore) UploadPart(ctx context.Context, upload MultipartUpload, partNumber int32, body io.ReadSeeker) (CompletedPart, error) {
+	path := filepath.Join(f.partsDir(upload.UploadID), fmt.Sprintf("%05d", partNumber))
+	out, err := os.Create(path)
+	if err != nil {
+		return CompletedPart{}, err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, body); err != nil {
+		return CompletedPart{}, err
+	}
+	return CompletedPart{PartNumber: partNumber, ETag: fmt.Sprintf("part-%d", partNumber)}, nil
+}
+
+func (f *LocalFileStore) CompleteMultipart(ctx context.Context, upload MultipartUpload, parts []CompletedPart) error {
+	destPath := filepath.Join(f.root, upload.Key)
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return err
+	}
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	sorted := append([]CompletedPart(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+
+	for _, p := range sorted {
+		partPath := filepath.Join(f.partsDir(upload.UploadID), fmt.Sprintf("%05d", p.PartNumber))
+		part, err := os.Open(partPath)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dest, part)
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(f.partsDir(upload.UploadID))
+}
+
+func (f *LocalFileStore) Delete(ctx context.Context, key string) error {
+	return os.Remove(filepath.Join(f.root, key))
+}
+
+func (f *LocalFileStore) partsDir(uploadID string) string {
+	return filepath.Join(f.root, ".multipart", uploadID)
+}
@@ -0,0 +1,110 @@
+package filestore
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3FileStore is the production FileStore, backed by a single bucket.
+type S3FileStore struct {
+	client *s3.Client
+	bucket string
+}
+
+// NewS3FileStore returns a FileStore that reads and writes bucket via
+// client.
+func NewS3FileStore(client *s3.Client, bucket string) *S3FileStore {
+	return &S3FileStore{client: client, bucket: bucket}
+}
+
+func (f *S3FileStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, err := f.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &f.bucket,
+		Key:         &key,
+		Body:        body,
+		ContentType: &contentType,
+	})
+	return err
+}
+
+func (f *S3FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := f.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &f.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (f *S3FileStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(f.client)
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: &f.bucket,
+		Key:    &key,
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+func (f *S3FileStore) InitMultipart(ctx context.Context, key, contentType string) (MultipartUpload, error) {
+	out, err := f.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      &f.bucket,
+		Key:         &key,
+		ContentType: &contentType,
+	})
+	if err != nil {
+		return MultipartUpload{}, err
+	}
+	return MultipartUpload{Key: key, UploadID: aws.ToString(out.UploadId)}, nil
+}
+
+func (f *S3FileStore) UploadPart(ctx context.Context, upload MultipartUpload, partNumber int32, body io.ReadSeeker) (CompletedPart, error) {
+	out, err := f.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     &f.bucket,
+		Key:        &upload.Key,
+		UploadId:   &upload.UploadID,
+		PartNumber: &partNumber,
+		Body:       body,
+	})
+	if err != nil {
+		return CompletedPart{}, err
+	}
+	return CompletedPart{PartNumber: partNumber, ETag: aws.ToString(out.ETag)}, nil
+}
+
+func (f *S3FileStore) CompleteMultipart(ctx context.Context, upload MultipartUpload, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int32(p.PartNumber),
+		}
+	}
+
+	_, err := f.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   &f.bucket,
+		Key:      &upload.Key,
+		UploadId: &upload.UploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completed,
+		},
+	})
+	return err
+}
+
+func (f *S3FileStore) Delete(ctx context.Context, key string) error {
+	_, err := f.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &f.bucket,
+		Key:    &key,
+	})
+	return err
+}
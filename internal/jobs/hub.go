@@ -0,0 +1,54 @@
+package jobs
+
+import "sync"
+
+// Hub fans a Job's Events out to every interested subscriber, e.g. the
+// SSE handlers backing GET /api/videos/{id}/progress. It holds no history;
+// a subscriber that misses an event falls back to the persisted Store.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[string][]chan Event
+}
+
+// NewHub returns an empty Hub.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string][]chan Event)}
+}
+
+// Subscribe returns a channel of Events for jobID and a cancel func the
+// caller must invoke when it stops listening.
+func (h *Hub) Subscribe(jobID string) (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	h.mu.Lock()
+	h.subs[jobID] = append(h.subs[jobID], ch)
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		subs := h.subs[jobID]
+		for i, c := range subs {
+			if c == ch {
+				h.subs[jobID] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// Publish delivers evt to every current subscriber of jobID. A subscriber
+// whose buffer is full misses evt (it stays subscribed) rather than
+// blocking the worker that's publishing.
+func (h *Hub) Publish(jobID string, evt Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, ch := range h.subs[jobID] {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
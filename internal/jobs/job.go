@@ -0,0 +1,56 @@
+// Package jobs runs long-lived video processing work (fast-start, aspect
+// detection, transcoding, upload) on a background worker pool so the upload
+// handler can return as soon as the raw file is safely on disk, and exposes
+// job state so clients can poll or stream progress.
+package jobs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the coarse lifecycle state of a Job.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Stage names the step of the pipeline a running Job is currently in.
+type Stage string
+
+const (
+	StageFastStart   Stage = "fast_start"
+	StageAspect      Stage = "aspect_detect"
+	StagePeaks       Stage = "peaks"
+	StageTranscoding Stage = "transcoding"
+	StageDASH        Stage = "dash_packaging"
+	StageUploading   Stage = "uploading"
+)
+
+// Job tracks one upload's progress through the processing pipeline.
+type Job struct {
+	ID          string
+	VideoID     uuid.UUID
+	UserID      uuid.UUID
+	SourcePath  string
+	AspectRatio string // set once aspect detection finishes; callers that need to rebuild S3 keys (e.g. peaks) read it from the latest job
+	Status      Status
+	Stage       Stage
+	Percent     int
+	Error       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Event is the subset of Job state pushed to progress subscribers.
+type Event struct {
+	Stage   Stage  `json:"stage"`
+	Percent int    `json:"percent"`
+	Status  Status `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
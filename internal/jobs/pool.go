@@ -0,0 +1,93 @@
+package jobs
+
+import "context"
+
+// Processor does the actual work for a Job, calling report as it moves
+// through stages. It's supplied by the caller so this package stays
+// agnostic of ffmpeg, S3, and the rest of the video pipeline.
+type Processor func(ctx context.Context, job *Job, report func(Stage, int)) error
+
+// Pool runs queued jobs across a fixed number of worker goroutines,
+// persisting state to Store and publishing Events to Hub as each job
+// progresses.
+type Pool struct {
+	store   Store
+	hub     *Hub
+	process Processor
+	queue   chan string
+}
+
+// NewPool starts workers background goroutines pulling job IDs off an
+// internal queue and running them through process. It also requeues any
+// job Store still has marked pending or running, so a restart resumes
+// in-flight uploads instead of abandoning them.
+func NewPool(store Store, hub *Hub, workers int, process Processor) *Pool {
+	p := &Pool{
+		store:   store,
+		hub:     hub,
+		process: process,
+		queue:   make(chan string, 64),
+	}
+	for i := 0; i < workers; i++ {
+		go p.loop()
+	}
+	p.recoverIncomplete()
+	return p
+}
+
+// recoverIncomplete requeues jobs a previous process left pending or
+// running — e.g. a crash or restart mid-upload. Their SourcePath still
+// points at the buffered file on disk, since run only removes it (via the
+// Processor) once a job finishes, successfully or not, so reprocessing
+// from the top is safe.
+func (p *Pool) recoverIncomplete() {
+	incomplete, err := p.store.ListIncomplete()
+	if err != nil {
+		return
+	}
+	for _, job := range incomplete {
+		p.Enqueue(job.ID)
+	}
+}
+
+// Enqueue schedules an already-persisted job for processing.
+func (p *Pool) Enqueue(jobID string) {
+	p.queue <- jobID
+}
+
+func (p *Pool) loop() {
+	for jobID := range p.queue {
+		p.run(jobID)
+	}
+}
+
+func (p *Pool) run(jobID string) {
+	job, err := p.store.Get(jobID)
+	if err != nil {
+		return
+	}
+
+	job.Status = StatusRunning
+	p.store.Update(job)
+	p.hub.Publish(jobID, Event{Status: StatusRunning, Stage: job.Stage, Percent: job.Percent})
+
+	report := func(stage Stage, percent int) {
+		job.Stage = stage
+		job.Percent = percent
+		p.store.Update(job)
+		p.hub.Publish(jobID, Event{Status: StatusRunning, Stage: stage, Percent: percent})
+	}
+
+	if err := p.process(context.Background(), &job, report); err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		p.store.Update(job)
+		p.hub.Publish(jobID, Event{Status: StatusFailed, Stage: job.Stage, Percent: job.Percent, Error: job.Error})
+		return
+	}
+
+	job.Status = StatusDone
+	job.Percent = 100
+	p.store.Update(job)
+	p.hub.Publish(jobID, Event{Status: StatusDone, Stage: job.Stage, Percent: 100})
+}
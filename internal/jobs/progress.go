@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+)
+
+// ProgressReader wraps an io.Reader whose total size is known up front (a
+// file being uploaded, PCM being piped out of ffmpeg, ...) and reports the
+// running percentage to onProgress as bytes are read.
+type ProgressReader struct {
+	r          io.Reader
+	read       int64
+	total      int64
+	onProgress func(percent int)
+}
+
+// NewProgressReader returns a ProgressReader over r. total is the expected
+// byte count; if it's <= 0, percent is always reported as 0.
+func NewProgressReader(r io.Reader, total int64, onProgress func(percent int)) *ProgressReader {
+	return &ProgressReader{r: r, total: total, onProgress: onProgress}
+}
+
+func (p *ProgressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.read += int64(n)
+	if p.onProgress != nil {
+		p.onProgress(p.percent())
+	}
+	return n, err
+}
+
+func (p *ProgressReader) percent() int {
+	if p.total <= 0 {
+		return 0
+	}
+	pct := int(p.read * 100 / p.total)
+	if pct > 100 {
+		pct = 100
+	}
+	return pct
+}
+
+// Seek delegates to the wrapped reader when possible. The AWS SDK needs this
+// to retry a PutObject upload from the start.
+func (p *ProgressReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := p.r.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("jobs: underlying reader does not support seeking")
+	}
+	pos, err := seeker.Seek(offset, whence)
+	if err == nil && whence == io.SeekStart {
+		p.read = offset
+	}
+	return pos, err
+}
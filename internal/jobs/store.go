@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// Store persists Job state so an in-flight upload survives a server
+// restart; only the live progress stream is lost, not the work itself.
+type Store interface {
+	Create(job Job) error
+	Get(id string) (Job, error)
+	LatestForVideo(videoID uuid.UUID) (Job, error)
+	Update(job Job) error
+	ListIncomplete() ([]Job, error)
+}
+
+// SQLiteStore is the default Store, backed by a single SQLite file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (and if necessary creates) the jobs table in the
+// SQLite database at path.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS jobs (
+		id           TEXT PRIMARY KEY,
+		video_id     TEXT NOT NULL,
+		user_id      TEXT NOT NULL,
+		source_path  TEXT NOT NULL,
+		aspect_ratio TEXT NOT NULL DEFAULT '',
+		status       TEXT NOT NULL,
+		stage        TEXT NOT NULL,
+		percent      INTEGER NOT NULL,
+		error        TEXT NOT NULL,
+		created_at   DATETIME NOT NULL,
+		updated_at   DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_jobs_video_id ON jobs(video_id);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Create(job Job) error {
+	_, err := s.db.Exec(
+		`INSERT INTO jobs (id, video_id, user_id, source_path, aspect_ratio, status, stage, percent, error, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.VideoID.String(), job.UserID.String(), job.SourcePath, job.AspectRatio,
+		job.Status, job.Stage, job.Percent, job.Error, job.CreatedAt, job.UpdatedAt,
+	)
+	return err
+}
+
+func (s *SQLiteStore) Get(id string) (Job, error) {
+	row := s.db.QueryRow(
+		`SELECT id, video_id, user_id, source_path, aspect_ratio, status, stage, percent, error, created_at, updated_at
+		 FROM jobs WHERE id = ?`, id,
+	)
+	return scanJob(row)
+}
+
+func (s *SQLiteStore) LatestForVideo(videoID uuid.UUID) (Job, error) {
+	row := s.db.QueryRow(
+		`SELECT id, video_id, user_id, source_path, aspect_ratio, status, stage, percent, error, created_at, updated_at
+		 FROM jobs WHERE video_id = ? ORDER BY created_at DESC LIMIT 1`, videoID.String(),
+	)
+	return scanJob(row)
+}
+
+func (s *SQLiteStore) Update(job Job) error {
+	res, err := s.db.Exec(
+		`UPDATE jobs SET aspect_ratio = ?, status = ?, stage = ?, percent = ?, error = ?, updated_at = ?
+		 WHERE id = ?`,
+		job.AspectRatio, job.Status, job.Stage, job.Percent, job.Error, job.UpdatedAt, job.ID,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no job with id %s", job.ID)
+	}
+	return nil
+}
+
+// ListIncomplete returns every job still pending or running, so a restarted
+// Pool can requeue work a previous process was interrupted mid-way
+// through instead of abandoning it.
+func (s *SQLiteStore) ListIncomplete() ([]Job, error) {
+	rows, err := s.db.Query(
+		`SELECT id, video_id, user_id, source_path, aspect_ratio, status, stage, percent, error, created_at, updated_at
+		 FROM jobs WHERE status IN (?, ?)`, StatusPending, StatusRunning,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incomplete []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		incomplete = append(incomplete, job)
+	}
+	return incomplete, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (Job, error) {
+	var job Job
+	var videoID, userID string
+	if err := row.Scan(
+		&job.ID, &videoID, &userID, &job.SourcePath, &job.AspectRatio,
+		&job.Status, &job.Stage, &job.Percent, &job.Error,
+		&job.CreatedAt, &job.UpdatedAt,
+	); err != nil {
+		return Job{}, err
+	}
+
+	parsedVideoID, err := uuid.Parse(videoID)
+	if err != nil {
+		return Job{}, err
+	}
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return Job{}, err
+	}
+	job.VideoID = parsedVideoID
+	job.UserID = parsedUserID
+
+	return job, nil
+}
@@ -0,0 +1,175 @@
+// Package media builds multi-bitrate HLS renditions from a source video
+// using ffmpeg/ffprobe, so the HTTP layer only has to worry about where the
+// output lands (local disk, S3, ...).
+package media
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// Rendition is a single rung of the HLS ladder.
+type Rendition struct {
+	Name    string // e.g. "720p", also used as the stream_<name> directory
+	Height  int
+	Width   int // output width at Height that preserves the source's aspect ratio
+	Bitrate int // target video bitrate in bits/sec, passed to ffmpeg as -b:v
+}
+
+// standardLadder is ordered low to high so LadderFor can stop as soon as it
+// would start up-scaling the source.
+var standardLadder = []Rendition{
+	{Name: "240p", Height: 240, Bitrate: 400_000},
+	{Name: "480p", Height: 480, Bitrate: 1_000_000},
+	{Name: "720p", Height: 720, Bitrate: 2_500_000},
+	{Name: "1080p", Height: 1080, Bitrate: 5_000_000},
+}
+
+// ProbeDimensions returns the pixel width and height of the first video
+// stream reported by ffprobe, so callers can size the ladder without ever
+// up-scaling, and can derive each rung's width from the source's actual
+// aspect ratio instead of assuming 16:9.
+func ProbeDimensions(filePath string) (width, height int, err error) {
+	type respStruct struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	var stdout bytes.Buffer
+
+	cmd := exec.Command("ffprobe", "-v", "error", "-print_format", "json", "-show_streams", filePath)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return 0, 0, err
+	}
+
+	data := &respStruct{}
+	if err := json.Unmarshal(stdout.Bytes(), data); err != nil {
+		return 0, 0, err
+	}
+	for _, s := range data.Streams {
+		if s.CodecType == "video" && s.Width > 0 && s.Height > 0 {
+			return s.Width, s.Height, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no valid video stream in ffprobe output")
+}
+
+// LadderFor returns the rungs of the standard ladder that are at or below
+// sourceHeight, each with Width scaled from sourceWidth/sourceHeight so it
+// matches what transcodeRendition's "scale=-2:height" actually produces. If
+// the source is smaller than our lowest rung, it returns a single rendition
+// at the source's native dimensions rather than an empty ladder.
+func LadderFor(sourceWidth, sourceHeight int) []Rendition {
+	ladder := make([]Rendition, 0, len(standardLadder))
+	for _, r := range standardLadder {
+		if r.Height <= sourceHeight {
+			r.Width = scaledWidth(sourceWidth, sourceHeight, r.Height)
+			ladder = append(ladder, r)
+		}
+	}
+	if len(ladder) == 0 {
+		ladder = append(ladder, Rendition{
+			Name:    fmt.Sprintf("%dp", sourceHeight),
+			Height:  sourceHeight,
+			Width:   scaledWidth(sourceWidth, sourceHeight, sourceHeight),
+			Bitrate: standardLadder[0].Bitrate,
+		})
+	}
+	return ladder
+}
+
+// scaledWidth mirrors ffmpeg's "scale=-2:height" filter: the width that
+// preserves sourceWidth/sourceHeight at the given height, rounded to the
+// nearest even number (libx264 requires even dimensions).
+func scaledWidth(sourceWidth, sourceHeight, height int) int {
+	return 2 * int(math.Round(float64(sourceWidth)*float64(height)/float64(sourceHeight)/2))
+}
+
+// HLSOutput describes a completed transcode sitting on local disk, ready to
+// be uploaded.
+type HLSOutput struct {
+	Dir            string // temp directory holding master.m3u8 and stream_<name>/
+	MasterPlaylist string
+	Renditions     []Rendition
+}
+
+// TranscodeHLS runs one ffmpeg invocation per rendition in parallel and
+// writes a master playlist referencing each one. The caller is responsible
+// for removing HLSOutput.Dir once it has uploaded the contents.
+func TranscodeHLS(sourcePath string, ladder []Rendition) (*HLSOutput, error) {
+	if len(ladder) == 0 {
+		return nil, fmt.Errorf("empty rendition ladder")
+	}
+
+	outDir, err := os.MkdirTemp("", "hls-")
+	if err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(ladder))
+	for _, r := range ladder {
+		wg.Add(1)
+		go func(r Rendition) {
+			defer wg.Done()
+			if err := transcodeRendition(sourcePath, outDir, r); err != nil {
+				errs <- fmt.Errorf("rendition %s: %w", r.Name, err)
+			}
+		}(r)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			os.RemoveAll(outDir)
+			return nil, err
+		}
+	}
+
+	masterPath := filepath.Join(outDir, "master.m3u8")
+	if err := writeMasterPlaylist(masterPath, ladder); err != nil {
+		os.RemoveAll(outDir)
+		return nil, err
+	}
+
+	return &HLSOutput{Dir: outDir, MasterPlaylist: masterPath, Renditions: ladder}, nil
+}
+
+func transcodeRendition(sourcePath, outDir string, r Rendition) error {
+	streamDir := filepath.Join(outDir, "stream_"+r.Name)
+	if err := os.MkdirAll(streamDir, 0o755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-i", sourcePath,
+		"-vf", fmt.Sprintf("scale=-2:%d", r.Height),
+		"-c:v", "libx264", "-b:v", fmt.Sprintf("%d", r.Bitrate),
+		"-c:a", "aac",
+		"-f", "hls",
+		"-hls_time", "6",
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(streamDir, "segment_%03d.ts"),
+		filepath.Join(streamDir, "stream.m3u8"),
+	)
+	return cmd.Run()
+}
+
+func writeMasterPlaylist(path string, ladder []Rendition) error {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, r := range ladder {
+		fmt.Fprintf(&buf, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n", r.Bitrate, r.Width, r.Height)
+		fmt.Fprintf(&buf, "stream_%s/stream.m3u8\n", r.Name)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+const (
+	peakSampleRate = 44100
+	peaksPerSecond = 100
+	samplesPerPeak = peakSampleRate / peaksPerSecond
+)
+
+// Peak is the min/max sample pair covering one 1/peaksPerSecond slice of
+// audio, enough for a frontend to draw a waveform without the raw PCM.
+type Peak struct {
+	Min int16
+	Max int16
+}
+
+// ExtractPeaks decodes the audio track of sourcePath to mono pcm_s16le at
+// peakSampleRate via ffmpeg and reduces it to peaksPerSecond min/max pairs
+// per second, streaming the PCM through a pipe so the decoded audio is
+// never buffered in full.
+func ExtractPeaks(sourcePath string) ([]Peak, error) {
+	cmd := exec.Command("ffmpeg",
+		"-i", sourcePath,
+		"-vn",
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", peakSampleRate),
+		"-f", "s16le",
+		"pipe:1",
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	peaks, readErr := reducePCMToPeaks(stdout)
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return nil, readErr
+	}
+	if waitErr != nil {
+		return nil, waitErr
+	}
+	return peaks, nil
+}
+
+func reducePCMToPeaks(r io.Reader) ([]Peak, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	buf := make([]int16, samplesPerPeak)
+	var peaks []Peak
+
+	for {
+		n, err := readSamples(br, buf)
+		if n > 0 {
+			peaks = append(peaks, peakOf(buf[:n]))
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+	return peaks, nil
+}
+
+// readSamples fills buf with up to len(buf) little-endian int16 samples,
+// reporting io.EOF once the stream is exhausted even on a short final read.
+func readSamples(r io.Reader, buf []int16) (int, error) {
+	raw := make([]byte, len(buf)*2)
+	n, err := io.ReadFull(r, raw)
+	samples := n / 2
+	for i := 0; i < samples; i++ {
+		buf[i] = int16(binary.LittleEndian.Uint16(raw[i*2:]))
+	}
+
+	switch err {
+	case nil:
+		return samples, nil
+	case io.ErrUnexpectedEOF:
+		return samples, io.EOF
+	default:
+		return samples, err
+	}
+}
+
+func peakOf(samples []int16) Peak {
+	p := Peak{Min: samples[0], Max: samples[0]}
+	for _, s := range samples[1:] {
+		if s < p.Min {
+			p.Min = s
+		}
+		if s > p.Max {
+			p.Max = s
+		}
+	}
+	return p
+}
+
+// EncodePeaks serializes peaks as a compact binary blob: a little-endian
+// uint32 peak count, followed by one (min int16, max int16) pair per peak.
+func EncodePeaks(peaks []Peak) []byte {
+	buf := make([]byte, 4+len(peaks)*4)
+	binary.LittleEndian.PutUint32(buf, uint32(len(peaks)))
+	for i, p := range peaks {
+		off := 4 + i*4
+		binary.LittleEndian.PutUint16(buf[off:], uint16(p.Min))
+		binary.LittleEndian.PutUint16(buf[off+2:], uint16(p.Max))
+	}
+	return buf
+}
+
+// DecodePeaks parses the blob produced by EncodePeaks.
+func DecodePeaks(data []byte) ([]Peak, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("peaks blob too short")
+	}
+	count := binary.LittleEndian.Uint32(data)
+	want := 4 + int(count)*4
+	if len(data) < want {
+		return nil, fmt.Errorf("peaks blob truncated: want %d bytes, got %d", want, len(data))
+	}
+
+	peaks := make([]Peak, count)
+	for i := range peaks {
+		off := 4 + i*4
+		peaks[i] = Peak{
+			Min: int16(binary.LittleEndian.Uint16(data[off:])),
+			Max: int16(binary.LittleEndian.Uint16(data[off+2:])),
+		}
+	}
+	return peaks, nil
+}
@@ -0,0 +1,39 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// RewritePlaylist rewrites every URI line in an HLS playlist (.m3u8) by
+// calling sign with that line's path, relative to the playlist's own
+// location. Comment and blank lines pass through unchanged. HLS uses this
+// same line-based syntax for both master playlists (whose URIs are nested
+// rendition playlists) and media playlists (whose URIs are segment files),
+// so one rewriter serves both.
+func RewritePlaylist(data []byte, sign func(relPath string) (string, error)) ([]byte, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var out bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			out.WriteString(line)
+			out.WriteString("\n")
+			continue
+		}
+
+		signed, err := sign(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("sign playlist entry %q: %w", trimmed, err)
+		}
+		out.WriteString(signed)
+		out.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan playlist: %w", err)
+	}
+	return out.Bytes(), nil
+}
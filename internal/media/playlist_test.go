@@ -0,0 +1,54 @@
+package media
+
+import "testing"
+
+const masterPlaylistFixture = "#EXTM3U\n" +
+	"#EXT-X-STREAM-INF:BANDWIDTH=1000000,RESOLUTION=1280x720\n" +
+	"stream_720p/stream.m3u8\n"
+
+const renditionPlaylistFixture = "#EXTM3U\n" +
+	"#EXT-X-VERSION:3\n" +
+	"#EXT-X-TARGETDURATION:6\n" +
+	"#EXTINF:6.000000,\n" +
+	"segment_000.ts\n" +
+	"#EXTINF:6.000000,\n" +
+	"segment_001.ts\n" +
+	"#EXT-X-ENDLIST\n"
+
+func TestRewritePlaylistPassesNestedPlaylistsToSign(t *testing.T) {
+	var signed []string
+	out, err := RewritePlaylist([]byte(masterPlaylistFixture), func(relPath string) (string, error) {
+		signed = append(signed, relPath)
+		return "https://signed.example/" + relPath, nil
+	})
+	if err != nil {
+		t.Fatalf("RewritePlaylist: %v", err)
+	}
+	if len(signed) != 1 || signed[0] != "stream_720p/stream.m3u8" {
+		t.Fatalf("sign called with %v, want [stream_720p/stream.m3u8]", signed)
+	}
+	if got := string(out); got != "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1000000,RESOLUTION=1280x720\nhttps://signed.example/stream_720p/stream.m3u8\n" {
+		t.Errorf("unexpected rewritten master playlist:\n%s", got)
+	}
+}
+
+func TestRewritePlaylistSignsEverySegment(t *testing.T) {
+	var signed []string
+	_, err := RewritePlaylist([]byte(renditionPlaylistFixture), func(relPath string) (string, error) {
+		signed = append(signed, relPath)
+		return "https://signed.example/" + relPath, nil
+	})
+	if err != nil {
+		t.Fatalf("RewritePlaylist: %v", err)
+	}
+
+	want := []string{"segment_000.ts", "segment_001.ts"}
+	if len(signed) != len(want) {
+		t.Fatalf("signed %v, want %v", signed, want)
+	}
+	for i, w := range want {
+		if signed[i] != w {
+			t.Errorf("signed[%d] = %q, want %q", i, signed[i], w)
+		}
+	}
+}
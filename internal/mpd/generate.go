@@ -0,0 +1,41 @@
+// Package mpd generates and manipulates DASH manifests (.mpd files) for
+// transcoded videos.
+package mpd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// GenerateDASH runs ffmpeg's DASH muxer against sourcePath, producing a
+// manifest.mpd plus one file per representation in a fresh temp directory.
+// It passes -single_file 1 so each representation is addressed by a
+// literal BaseURL with byte-range SegmentBase, rather than a $Number$
+// template — that's what lets RewriteBaseURLs presign a single object per
+// representation instead of an unbounded set of segment URLs. The caller
+// is responsible for removing that directory once it has uploaded the
+// contents.
+func GenerateDASH(sourcePath string) (dir string, manifestPath string, err error) {
+	outDir, err := os.MkdirTemp("", "dash-")
+	if err != nil {
+		return "", "", err
+	}
+
+	manifestPath = filepath.Join(outDir, "manifest.mpd")
+	cmd := exec.Command("ffmpeg",
+		"-i", sourcePath,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-c:v", "libx264", "-c:a", "aac",
+		"-f", "dash",
+		"-seg_duration", "6",
+		"-single_file", "1",
+		manifestPath,
+	)
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(outDir)
+		return "", "", err
+	}
+
+	return outDir, manifestPath, nil
+}
@@ -0,0 +1,152 @@
+package mpd
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// MPD mirrors just the parts of a DASH manifest we need to validate and
+// rewrite; unrecognized elements round-trip untouched.
+type MPD struct {
+	XMLName xml.Name       `xml:"MPD"`
+	Attrs   []xml.Attr     `xml:",any,attr"`
+	Periods []Period       `xml:"Period"`
+	Extra   []extraElement `xml:",any"`
+}
+
+type Period struct {
+	Attrs          []xml.Attr      `xml:",any,attr"`
+	AdaptationSets []AdaptationSet `xml:"AdaptationSet"`
+	Extra          []extraElement  `xml:",any"`
+}
+
+type AdaptationSet struct {
+	Attrs           []xml.Attr       `xml:",any,attr"`
+	Representations []Representation `xml:"Representation"`
+	Extra           []extraElement   `xml:",any"`
+}
+
+type Representation struct {
+	ID              string           `xml:"id,attr"`
+	Bandwidth       int              `xml:"bandwidth,attr"`
+	Attrs           []xml.Attr       `xml:",any,attr"`
+	BaseURL         string           `xml:"BaseURL,omitempty"`
+	SegmentBase     *SegmentBase     `xml:"SegmentBase"`
+	SegmentTemplate *SegmentTemplate `xml:"SegmentTemplate"`
+	Extra           []extraElement   `xml:",any"`
+}
+
+// extraElement holds a child element the types above don't model, so it is
+// written back out as it was read.
+type extraElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// SegmentBase is byte-range addressing into a single file: the whole
+// representation lives at BaseURL, and the initialization segment is a
+// range carved out of it. This is what ffmpeg's dash muxer emits with
+// -single_file 1, which is what GenerateDASH uses.
+type SegmentBase struct {
+	IndexRange     string `xml:"indexRange,attr"`
+	Initialization struct {
+		Range string `xml:"range,attr"`
+	} `xml:"Initialization"`
+}
+
+// SegmentTemplate is $Number$/$Time$ template addressing across many
+// segment files. GenerateDASH doesn't produce this (it forces single-file
+// byte-range addressing instead, so every representation is one signable
+// object), but Parse/Validate still recognize it for manifests read from
+// elsewhere.
+type SegmentTemplate struct {
+	Duration  int `xml:"duration,attr"`
+	Timescale int `xml:"timescale,attr"`
+}
+
+// Parse unmarshals a DASH manifest document.
+func Parse(data []byte) (*MPD, error) {
+	var doc MPD
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("mpd: parse: %w", err)
+	}
+	return &doc, nil
+}
+
+// Validate sanity-checks the invariants downstream code relies on: at least
+// one period, every representation has a unique ID and a BaseURL to sign,
+// and its segment addressing (SegmentBase or SegmentTemplate) is usable.
+func (m *MPD) Validate() error {
+	if len(m.Periods) == 0 {
+		return fmt.Errorf("mpd: manifest has no periods")
+	}
+
+	seenIDs := make(map[string]bool)
+	for _, period := range m.Periods {
+		if len(period.AdaptationSets) == 0 {
+			return fmt.Errorf("mpd: period has no adaptation sets")
+		}
+		for _, as := range period.AdaptationSets {
+			for _, rep := range as.Representations {
+				if rep.ID == "" {
+					return fmt.Errorf("mpd: representation missing id")
+				}
+				if seenIDs[rep.ID] {
+					return fmt.Errorf("mpd: duplicate representation id %q", rep.ID)
+				}
+				seenIDs[rep.ID] = true
+
+				if rep.BaseURL == "" {
+					return fmt.Errorf("mpd: representation %q has no BaseURL", rep.ID)
+				}
+
+				switch {
+				case rep.SegmentBase != nil:
+					if rep.SegmentBase.IndexRange == "" {
+						return fmt.Errorf("mpd: representation %q has no segment index range", rep.ID)
+					}
+				case rep.SegmentTemplate != nil:
+					if rep.SegmentTemplate.Duration <= 0 {
+						return fmt.Errorf("mpd: representation %q has non-positive segment duration", rep.ID)
+					}
+				default:
+					return fmt.Errorf("mpd: representation %q has no segment addressing", rep.ID)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RewriteBaseURLs replaces every Representation's BaseURL with the result
+// of sign(relativePath). Presigned URLs expire faster than we'd want to
+// cache a manifest for, so this runs at request time rather than once at
+// publish time.
+func (m *MPD) RewriteBaseURLs(sign func(relPath string) (string, error)) error {
+	for pi := range m.Periods {
+		for ai := range m.Periods[pi].AdaptationSets {
+			reps := m.Periods[pi].AdaptationSets[ai].Representations
+			for ri := range reps {
+				if reps[ri].BaseURL == "" {
+					continue
+				}
+				signed, err := sign(reps[ri].BaseURL)
+				if err != nil {
+					return fmt.Errorf("mpd: signing BaseURL for representation %q: %w", reps[ri].ID, err)
+				}
+				reps[ri].BaseURL = signed
+			}
+		}
+	}
+	return nil
+}
+
+// Marshal serializes the manifest back to XML with a standard header.
+func (m *MPD) Marshal() ([]byte, error) {
+	body, err := xml.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
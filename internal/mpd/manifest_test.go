@@ -0,0 +1,89 @@
+package mpd
+
+import "testing"
+
+// singleFileFixture mirrors what ffmpeg's dash muxer actually emits with
+// -single_file 1: one BaseURL per representation, addressed by byte range
+// via SegmentBase, rather than a $Number$ template.
+const singleFileFixture = `<?xml version="1.0" encoding="utf-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="static" mediaPresentationDuration="PT10.000S" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011">
+  <Period duration="PT10.000S">
+    <AdaptationSet id="0" contentType="video">
+      <Representation id="0" mimeType="video/mp4" codecs="avc1.640020" bandwidth="1000000">
+        <BaseURL>chunk-stream0.m4s</BaseURL>
+        <SegmentBase indexRange="878-953">
+          <Initialization range="0-877"/>
+        </SegmentBase>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation id="1" mimeType="audio/mp4" codecs="mp4a.40.2" bandwidth="128000">
+        <BaseURL>chunk-stream1.m4s</BaseURL>
+        <SegmentBase indexRange="752-827">
+          <Initialization range="0-751"/>
+        </SegmentBase>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+func TestParseAndValidateSingleFileFixture(t *testing.T) {
+	doc, err := Parse([]byte(singleFileFixture))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestValidateRejectsMissingBaseURL(t *testing.T) {
+	doc := &MPD{Periods: []Period{{AdaptationSets: []AdaptationSet{{Representations: []Representation{
+		{ID: "0", SegmentBase: &SegmentBase{IndexRange: "0-10"}},
+	}}}}}}
+
+	if err := doc.Validate(); err == nil {
+		t.Fatal("expected error for representation with no BaseURL, got nil")
+	}
+}
+
+func TestValidateRejectsSegmentTemplateWithoutDuration(t *testing.T) {
+	doc := &MPD{Periods: []Period{{AdaptationSets: []AdaptationSet{{Representations: []Representation{
+		{ID: "0", BaseURL: "rep0", SegmentTemplate: &SegmentTemplate{Duration: 0}},
+	}}}}}}
+
+	if err := doc.Validate(); err == nil {
+		t.Fatal("expected error for SegmentTemplate with non-positive duration, got nil")
+	}
+}
+
+func TestRewriteBaseURLsSignsEachRepresentation(t *testing.T) {
+	doc, err := Parse([]byte(singleFileFixture))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var signed []string
+	err = doc.RewriteBaseURLs(func(relPath string) (string, error) {
+		signed = append(signed, relPath)
+		return "https://signed.example/" + relPath, nil
+	})
+	if err != nil {
+		t.Fatalf("RewriteBaseURLs: %v", err)
+	}
+
+	wantSigned := []string{"chunk-stream0.m4s", "chunk-stream1.m4s"}
+	if len(signed) != len(wantSigned) {
+		t.Fatalf("signed %d paths, want %d: %v", len(signed), len(wantSigned), signed)
+	}
+	for i, want := range wantSigned {
+		if signed[i] != want {
+			t.Errorf("signed path %d = %q, want %q", i, signed[i], want)
+		}
+	}
+
+	got := doc.Periods[0].AdaptationSets[0].Representations[0].BaseURL
+	if got != "https://signed.example/chunk-stream0.m4s" {
+		t.Errorf("representation 0 BaseURL = %q, want signed URL", got)
+	}
+}
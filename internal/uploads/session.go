@@ -0,0 +1,42 @@
+// Package uploads persists resumable multipart upload sessions, so a large
+// video upload can survive a network drop: the client re-fetches which
+// parts already landed and only resends what's missing.
+package uploads
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PartRecord is one part that has already landed for a Session.
+type PartRecord struct {
+	PartNumber int32
+	ETag       string
+	Size       int64
+	Checksum   string // sha256 hex of the part body, so a resumed client can verify a re-sent part matches
+}
+
+// Session tracks a single resumable upload from creation through
+// completion.
+type Session struct {
+	ID        string // exposed to the client as the upload ID
+	VideoID   uuid.UUID
+	UserID    uuid.UUID
+	Key       string // filestore key the assembled object will live at
+	UploadID  string // underlying FileStore multipart upload ID
+	Parts     []PartRecord
+	Completed bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ReceivedPartNumbers returns the part numbers already recorded, so a
+// resuming client knows what it still needs to send.
+func (s Session) ReceivedPartNumbers() []int32 {
+	nums := make([]int32, len(s.Parts))
+	for i, p := range s.Parts {
+		nums[i] = p.PartNumber
+	}
+	return nums
+}
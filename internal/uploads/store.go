@@ -0,0 +1,137 @@
+package uploads
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// Store persists Sessions and their parts across restarts.
+type Store interface {
+	Create(session Session) error
+	Get(id string) (Session, error)
+	AddPart(id string, part PartRecord) error
+	Complete(id string) error
+}
+
+// SQLiteStore is the default Store.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (and if necessary creates) the upload session tables
+// in the SQLite database at path.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS upload_sessions (
+		id         TEXT PRIMARY KEY,
+		video_id   TEXT NOT NULL,
+		user_id    TEXT NOT NULL,
+		key        TEXT NOT NULL,
+		upload_id  TEXT NOT NULL,
+		completed  INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS upload_parts (
+		session_id  TEXT NOT NULL,
+		part_number INTEGER NOT NULL,
+		etag        TEXT NOT NULL,
+		size        INTEGER NOT NULL,
+		checksum    TEXT NOT NULL,
+		PRIMARY KEY (session_id, part_number)
+	);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Create(session Session) error {
+	_, err := s.db.Exec(
+		`INSERT INTO upload_sessions (id, video_id, user_id, key, upload_id, completed, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.ID, session.VideoID.String(), session.UserID.String(), session.Key, session.UploadID,
+		session.Completed, session.CreatedAt, session.UpdatedAt,
+	)
+	return err
+}
+
+func (s *SQLiteStore) Get(id string) (Session, error) {
+	row := s.db.QueryRow(
+		`SELECT id, video_id, user_id, key, upload_id, completed, created_at, updated_at
+		 FROM upload_sessions WHERE id = ?`, id,
+	)
+
+	var session Session
+	var videoID, userID string
+	if err := row.Scan(
+		&session.ID, &videoID, &userID, &session.Key, &session.UploadID,
+		&session.Completed, &session.CreatedAt, &session.UpdatedAt,
+	); err != nil {
+		return Session{}, err
+	}
+
+	parsedVideoID, err := uuid.Parse(videoID)
+	if err != nil {
+		return Session{}, err
+	}
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return Session{}, err
+	}
+	session.VideoID = parsedVideoID
+	session.UserID = parsedUserID
+
+	rows, err := s.db.Query(
+		`SELECT part_number, etag, size, checksum FROM upload_parts WHERE session_id = ? ORDER BY part_number`, id,
+	)
+	if err != nil {
+		return Session{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p PartRecord
+		if err := rows.Scan(&p.PartNumber, &p.ETag, &p.Size, &p.Checksum); err != nil {
+			return Session{}, err
+		}
+		session.Parts = append(session.Parts, p)
+	}
+
+	return session, rows.Err()
+}
+
+func (s *SQLiteStore) AddPart(id string, part PartRecord) error {
+	_, err := s.db.Exec(
+		`INSERT INTO upload_parts (session_id, part_number, etag, size, checksum) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (session_id, part_number) DO UPDATE SET etag = excluded.etag, size = excluded.size, checksum = excluded.checksum`,
+		id, part.PartNumber, part.ETag, part.Size, part.Checksum,
+	)
+	return err
+}
+
+func (s *SQLiteStore) Complete(id string) error {
+	res, err := s.db.Exec(`UPDATE upload_sessions SET completed = 1, updated_at = updated_at WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no upload session with id %s", id)
+	}
+	return nil
+}